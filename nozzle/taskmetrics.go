@@ -0,0 +1,77 @@
+// Package nozzle wires cfclient's task event source into the nozzle's New
+// Relic metrics pipeline, alongside the existing firehose app/container
+// metrics.
+package nozzle
+
+import (
+	"context"
+	"strings"
+
+	"github.com/cloudfoundry-community/go-cfclient"
+)
+
+// Application is the subset of newrelic.Application this pipeline needs;
+// *newrelic.Application satisfies it directly.
+type Application interface {
+	RecordCustomEvent(eventType string, params map[string]interface{}) error
+}
+
+// TaskMetricsPipeline drains a TaskWatcher's event stream and records each
+// TaskEvent as a "tasks" custom event, so task counts per app/state
+// (`state`/`appGuid`), failure rates (`failureReason`), and memory/disk
+// usage land in New Relic the same way the firehose's existing custom
+// events do. Counts and failure rates are computed in New Relic via NRQL
+// over these per-event facts rather than pre-aggregated here.
+type TaskMetricsPipeline struct {
+	Watcher     *cfclient.TaskWatcher
+	Application Application
+}
+
+// NewTaskMetricsPipeline returns a TaskMetricsPipeline that records watcher's
+// events against app.
+func NewTaskMetricsPipeline(watcher *cfclient.TaskWatcher, app Application) *TaskMetricsPipeline {
+	return &TaskMetricsPipeline{Watcher: watcher, Application: app}
+}
+
+// Run starts watcher's poll loop and records a "tasks" custom event for
+// every TaskEvent it emits, until ctx is cancelled or the watcher's event
+// channel closes. It should be run in its own goroutine.
+func (p *TaskMetricsPipeline) Run(ctx context.Context) error {
+	go p.Watcher.Start(ctx)
+
+	for event := range p.Watcher.Events() {
+		if err := p.Application.RecordCustomEvent("tasks", taskEventParams(event)); err != nil {
+			return err
+		}
+	}
+	return ctx.Err()
+}
+
+// taskEventParams flattens a TaskEvent into the attributes of a "tasks"
+// custom event.
+func taskEventParams(event cfclient.TaskEvent) map[string]interface{} {
+	params := map[string]interface{}{
+		"changeType": string(event.Type),
+		"taskGuid":   event.Task.GUID,
+		"taskName":   event.Task.Name,
+		"appGuid":    appGUID(event.Task),
+		"state":      event.Task.State,
+		"prevState":  event.PrevState,
+		"memoryInMb": event.Task.MemoryInMb,
+		"diskInMb":   event.Task.DiskInMb,
+	}
+	if event.Task.Result.FailureReason != "" {
+		params["failureReason"] = event.Task.Result.FailureReason
+	}
+	return params
+}
+
+// appGUID extracts the app GUID from a task's app link, e.g.
+// ".../v3/apps/<guid>" -> "<guid>".
+func appGUID(t cfclient.Task) string {
+	href := t.Links.App.Href
+	if i := strings.LastIndex(href, "/"); i >= 0 {
+		return href[i+1:]
+	}
+	return href
+}
@@ -0,0 +1,99 @@
+package nozzle
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cloudfoundry-community/go-cfclient"
+)
+
+type recordingApplication struct {
+	mu     sync.Mutex
+	events []map[string]interface{}
+}
+
+func (a *recordingApplication) RecordCustomEvent(eventType string, params map[string]interface{}) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.events = append(a.events, params)
+	return nil
+}
+
+func (a *recordingApplication) recorded() []map[string]interface{} {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return append([]map[string]interface{}(nil), a.events...)
+}
+
+func TestTaskMetricsPipelineRecordsTaskEvents(t *testing.T) {
+	resp, err := json.Marshal(cfclient.TaskListResponse{
+		Tasks: []cfclient.Task{{
+			GUID:       "task-1",
+			Name:       "migrate",
+			State:      "FAILED",
+			MemoryInMb: 256,
+			DiskInMb:   512,
+			Result:     struct {
+				FailureReason string `json:"failure_reason"`
+			}{FailureReason: "exit code 1"},
+		}},
+	})
+	if err != nil {
+		t.Fatalf("marshal fixture: %v", err)
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(resp)
+	}))
+	defer srv.Close()
+
+	c := &cfclient.Client{BaseURL: srv.URL, HTTPClient: &http.Client{}}
+	watcher := cfclient.NewTaskWatcher(c, cfclient.TaskWatcherOptions{PollInterval: time.Hour})
+	app := &recordingApplication{}
+	pipeline := NewTaskMetricsPipeline(watcher, app)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- pipeline.Run(ctx) }()
+
+	deadline := time.After(time.Second)
+	for {
+		if len(app.recorded()) > 0 {
+			break
+		}
+		select {
+		case <-deadline:
+			t.Fatalf("timed out waiting for a recorded tasks event")
+		case <-time.After(time.Millisecond):
+		}
+	}
+
+	cancel()
+	<-done
+
+	events := app.recorded()
+	if len(events) != 1 {
+		t.Fatalf("expected 1 recorded event, got %d", len(events))
+	}
+	got := events[0]
+	if got["changeType"] != string(cfclient.TaskCreated) {
+		t.Errorf("changeType = %v, want %v", got["changeType"], cfclient.TaskCreated)
+	}
+	if got["taskGuid"] != "task-1" {
+		t.Errorf("taskGuid = %v, want task-1", got["taskGuid"])
+	}
+	if got["state"] != "FAILED" {
+		t.Errorf("state = %v, want FAILED", got["state"])
+	}
+	if got["failureReason"] != "exit code 1" {
+		t.Errorf("failureReason = %v, want %q", got["failureReason"], "exit code 1")
+	}
+	if got["memoryInMb"] != 256 {
+		t.Errorf("memoryInMb = %v, want 256", got["memoryInMb"])
+	}
+}
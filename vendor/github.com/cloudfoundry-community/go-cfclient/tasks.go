@@ -6,6 +6,9 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
+	"net/url"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/pkg/errors"
@@ -22,12 +25,51 @@ type TaskListResponse struct {
 		Last struct {
 			Href string `json:"href"`
 		} `json:"last"`
-		Next     interface{} `json:"next"`
-		Previous interface{} `json:"previous"`
+		Next *struct {
+			Href string `json:"href"`
+		} `json:"next"`
+		Previous *struct {
+			Href string `json:"href"`
+		} `json:"previous"`
 	} `json:"pagination"`
 	Tasks []Task `json:"resources"`
 }
 
+// TaskListOptions holds the query parameters accepted by the v3 tasks list
+// endpoint. Zero values are omitted from the request.
+type TaskListOptions struct {
+	PerPage    int
+	States     []string
+	Names      []string
+	AppGUIDs   []string
+	SpaceGUIDs []string
+	OrderBy    string
+}
+
+// queryString renders the options as a URL query string, e.g. "per_page=50&states=RUNNING".
+func (o TaskListOptions) queryString() string {
+	values := url.Values{}
+	if o.PerPage > 0 {
+		values.Set("per_page", strconv.Itoa(o.PerPage))
+	}
+	if len(o.States) > 0 {
+		values.Set("states", strings.Join(o.States, ","))
+	}
+	if len(o.Names) > 0 {
+		values.Set("names", strings.Join(o.Names, ","))
+	}
+	if len(o.AppGUIDs) > 0 {
+		values.Set("app_guids", strings.Join(o.AppGUIDs, ","))
+	}
+	if len(o.SpaceGUIDs) > 0 {
+		values.Set("space_guids", strings.Join(o.SpaceGUIDs, ","))
+	}
+	if o.OrderBy != "" {
+		values.Set("order_by", o.OrderBy)
+	}
+	return values.Encode()
+}
+
 // Task is a description of a task element.
 type Task struct {
 	GUID       string `json:"guid"`
@@ -66,19 +108,58 @@ type TaskRequest struct {
 	DropletGUID      string `json:"droplet_guid"`
 }
 
-func (c *Client) makeTaskListRequest() ([]byte, error) {
-	req := c.NewRequest("GET", "/v3/tasks")
+func (c *Client) makeTaskListRequest(path string) ([]byte, error) {
+	req := c.NewRequest("GET", path)
 	resp, err := c.DoRequest(req)
 	if err != nil {
 		return nil, errors.Wrap(err, "Error requesting tasks")
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode != 200 {
-		return nil, errors.Wrapf(err, "Error requesting tasks: status code not 200, it was %d", resp.StatusCode)
+		return nil, errors.Errorf("Error requesting tasks: status code not 200, it was %d", resp.StatusCode)
 	}
 	return ioutil.ReadAll(resp.Body)
 }
 
+// relativeTaskHref strips the scheme and host off a pagination href, leaving
+// a path+query suitable for c.NewRequest, which expects foundation-relative
+// paths rather than the absolute URLs the API embeds in "next"/"previous".
+func relativeTaskHref(href string) (string, error) {
+	u, err := url.Parse(href)
+	if err != nil {
+		return "", errors.Wrap(err, "Error parsing pagination href")
+	}
+	if u.RawQuery == "" {
+		return u.Path, nil
+	}
+	return u.Path + "?" + u.RawQuery, nil
+}
+
+// listAllTasksFrom follows pagination.next.href starting at path until the
+// API reports no further pages, accumulating every task along the way.
+func (c *Client) listAllTasksFrom(path string) ([]Task, error) {
+	var tasks []Task
+	for path != "" {
+		body, err := c.makeTaskListRequest(path)
+		if err != nil {
+			return nil, errors.Wrap(err, "Error requesting tasks")
+		}
+		response, err := parseTaskListRespones(body)
+		if err != nil {
+			return nil, errors.Wrap(err, "Error reading tasks")
+		}
+		tasks = append(tasks, response.Tasks...)
+		if response.Pagination.Next == nil {
+			break
+		}
+		path, err = relativeTaskHref(response.Pagination.Next.Href)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return tasks, nil
+}
+
 func parseTaskListRespones(answer []byte) (TaskListResponse, error) {
 	var response TaskListResponse
 	err := json.Unmarshal(answer, &response)
@@ -88,9 +169,10 @@ func parseTaskListRespones(answer []byte) (TaskListResponse, error) {
 	return response, nil
 }
 
-// ListTasks returns all tasks the user has access to.
+// ListTasks returns the first page of tasks the user has access to. Callers
+// that need every task, not just the first page, should use ListAllTasks.
 func (c *Client) ListTasks() ([]Task, error) {
-	body, err := c.makeTaskListRequest()
+	body, err := c.makeTaskListRequest("/v3/tasks")
 	if err != nil {
 		return nil, errors.Wrap(err, "Error requesting tasks")
 	}
@@ -101,6 +183,16 @@ func (c *Client) ListTasks() ([]Task, error) {
 	return response.Tasks, nil
 }
 
+// ListAllTasks returns every task the user has access to, following
+// pagination.next.href until the API reports no further pages.
+func (c *Client) ListAllTasks(opts TaskListOptions) ([]Task, error) {
+	path := "/v3/tasks"
+	if q := opts.queryString(); q != "" {
+		path = path + "?" + q
+	}
+	return c.listAllTasksFrom(path)
+}
+
 func createReader(tr TaskRequest) (io.Reader, error) {
 	rmap := make(map[string]string)
 	rmap["command"] = tr.Command
@@ -175,6 +267,7 @@ func (c *Client) TaskByGuid(guid string) (task Task, err error) {
 }
 
 // TasksByApp retuns task structures which aligned to an app identified by the given guid.
+// This only returns the first page; use TasksByAppAll to fetch every task.
 func (c *Client) TasksByApp(guid string) ([]Task, error) {
 	request := fmt.Sprintf("/v3/apps/%s/tasks", guid)
 	req := c.NewRequest("GET", request)
@@ -197,6 +290,16 @@ func (c *Client) TasksByApp(guid string) ([]Task, error) {
 	return response.Tasks, nil
 }
 
+// TasksByAppAll returns every task aligned to the app identified by guid,
+// following pagination.next.href until the API reports no further pages.
+func (c *Client) TasksByAppAll(guid string, opts TaskListOptions) ([]Task, error) {
+	path := fmt.Sprintf("/v3/apps/%s/tasks", guid)
+	if q := opts.queryString(); q != "" {
+		path = path + "?" + q
+	}
+	return c.listAllTasksFrom(path)
+}
+
 // TerminateTask cancels a task identified by its GUID.
 func (c *Client) TerminateTask(guid string) error {
 	req := c.NewRequest("PUT", fmt.Sprintf("/v3/tasks/%s/cancel", guid))
@@ -207,7 +310,7 @@ func (c *Client) TerminateTask(guid string) error {
 	defer resp.Body.Close()
 
 	if resp.StatusCode != 202 {
-		return errors.Wrapf(err, "Failed terminating task, response status code %d", resp.StatusCode)
+		return errors.Errorf("Failed terminating task, response status code %d", resp.StatusCode)
 	}
 	return nil
 }
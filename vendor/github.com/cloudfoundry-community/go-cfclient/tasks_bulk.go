@@ -0,0 +1,189 @@
+package cfclient
+
+import (
+	"context"
+	"sync"
+)
+
+// runToCompletion strips ctx's cancellation (and deadline) before handing it
+// to an already-dispatched request. Once a worker has pulled a job off the
+// queue, ctx being cancelled should stop further dispatch, not truncate the
+// request that's already in flight, or CreateTasks's "waits for in-flight
+// requests to drain" promise doesn't hold. context.WithoutCancel keeps any
+// values on ctx (so a TaskClient's own per-request timeout/retry policy
+// still applies) while leaving this one immune to the batch-level cancel.
+func runToCompletion(ctx context.Context) context.Context {
+	return context.WithoutCancel(ctx)
+}
+
+// BulkOptions configures a bulk task operation such as CreateTasks or
+// TerminateTasks.
+type BulkOptions struct {
+	// Concurrency caps how many requests are in flight at once. Defaults
+	// to 10 when zero or negative.
+	Concurrency int
+	// Progress, if set, is called after each request completes with the
+	// number done so far and the total, so operators driving large batch
+	// jobs get live progress.
+	Progress func(done, total int)
+}
+
+// concurrency returns the worker count to use for a batch of size n.
+func (o BulkOptions) concurrency(n int) int {
+	c := o.Concurrency
+	if c <= 0 {
+		c = 10
+	}
+	if c > n {
+		c = n
+	}
+	return c
+}
+
+// TaskResult is the outcome of one request in a bulk task operation. Request
+// is populated by CreateTasks, GUID by TerminateTasks; Task and Err are
+// populated by both.
+type TaskResult struct {
+	Request TaskRequest
+	GUID    string
+	Task    Task
+	Err     error
+}
+
+// CreateTasks submits reqs concurrently with a worker pool sized by
+// opts.Concurrency, aggregating each request's success or failure into a
+// TaskResult rather than failing the whole batch on the first error. Once
+// ctx is cancelled, no further requests are dispatched, but in-flight ones
+// are allowed to drain; undispatched requests come back with Err set to
+// ctx.Err().
+func (c *Client) CreateTasks(ctx context.Context, reqs []TaskRequest, opts BulkOptions) ([]TaskResult, error) {
+	return createTasks(ctx, reqs, opts, c.CreateTaskWithContext)
+}
+
+// CreateTasks overrides Client.CreateTasks so that a TaskClient's default
+// timeout and retry policy apply to every request in the batch, not just
+// calls made directly through tc.
+func (tc *TaskClient) CreateTasks(ctx context.Context, reqs []TaskRequest, opts BulkOptions) ([]TaskResult, error) {
+	return createTasks(ctx, reqs, opts, tc.CreateTaskWithContext)
+}
+
+func createTasks(ctx context.Context, reqs []TaskRequest, opts BulkOptions, create func(context.Context, TaskRequest) (Task, error)) ([]TaskResult, error) {
+	results := make([]TaskResult, len(reqs))
+	if len(reqs) == 0 {
+		return results, nil
+	}
+
+	dispatched := make([]bool, len(reqs))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	done := 0
+
+	concurrency := opts.concurrency(len(reqs))
+	wg.Add(concurrency)
+	for w := 0; w < concurrency; w++ {
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				task, err := create(runToCompletion(ctx), reqs[i])
+				results[i] = TaskResult{Request: reqs[i], Task: task, Err: err}
+
+				mu.Lock()
+				done++
+				if opts.Progress != nil {
+					opts.Progress(done, len(reqs))
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+dispatchLoop:
+	for i := range reqs {
+		select {
+		case jobs <- i:
+			dispatched[i] = true
+		case <-ctx.Done():
+			break dispatchLoop
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		for i, ok := range dispatched {
+			if !ok {
+				results[i] = TaskResult{Request: reqs[i], Err: err}
+			}
+		}
+		return results, err
+	}
+	return results, nil
+}
+
+// TerminateTasks cancels the tasks identified by guids concurrently, with
+// the same worker-pool and partial-failure shape as CreateTasks.
+func (c *Client) TerminateTasks(ctx context.Context, guids []string, opts BulkOptions) ([]TaskResult, error) {
+	return terminateTasks(ctx, guids, opts, c.TerminateTaskWithContext)
+}
+
+// TerminateTasks overrides Client.TerminateTasks so that a TaskClient's
+// default timeout and retry policy apply to every cancellation in the
+// batch, not just calls made directly through tc.
+func (tc *TaskClient) TerminateTasks(ctx context.Context, guids []string, opts BulkOptions) ([]TaskResult, error) {
+	return terminateTasks(ctx, guids, opts, tc.TerminateTaskWithContext)
+}
+
+func terminateTasks(ctx context.Context, guids []string, opts BulkOptions, terminate func(context.Context, string) error) ([]TaskResult, error) {
+	results := make([]TaskResult, len(guids))
+	if len(guids) == 0 {
+		return results, nil
+	}
+
+	dispatched := make([]bool, len(guids))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	done := 0
+
+	concurrency := opts.concurrency(len(guids))
+	wg.Add(concurrency)
+	for w := 0; w < concurrency; w++ {
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				err := terminate(runToCompletion(ctx), guids[i])
+				results[i] = TaskResult{GUID: guids[i], Err: err}
+
+				mu.Lock()
+				done++
+				if opts.Progress != nil {
+					opts.Progress(done, len(guids))
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+dispatchLoop:
+	for i := range guids {
+		select {
+		case jobs <- i:
+			dispatched[i] = true
+		case <-ctx.Done():
+			break dispatchLoop
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		for i, ok := range dispatched {
+			if !ok {
+				results[i] = TaskResult{GUID: guids[i], Err: err}
+			}
+		}
+		return results, err
+	}
+	return results, nil
+}
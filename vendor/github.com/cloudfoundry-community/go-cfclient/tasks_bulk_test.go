@@ -0,0 +1,83 @@
+package cfclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// Once ctx is cancelled mid-batch, already-dispatched requests must be
+// allowed to drain and report their real outcome rather than an abandoned
+// context.Canceled error.
+func TestCreateTasksDrainsInFlightRequestsAfterCancel(t *testing.T) {
+	var inFlight int32
+	block := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&inFlight, 1)
+		<-block
+		w.Write([]byte(`{"guid":"x"}`))
+	}))
+	defer srv.Close()
+
+	c := &Client{BaseURL: srv.URL, HTTPClient: &http.Client{}}
+	reqs := make([]TaskRequest, 5)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	go func() {
+		time.Sleep(30 * time.Millisecond)
+		cancel()
+		time.Sleep(30 * time.Millisecond)
+		close(block)
+	}()
+
+	results, err := c.CreateTasks(ctx, reqs, BulkOptions{Concurrency: 5})
+	if err == nil {
+		t.Fatalf("expected CreateTasks to report the batch-level cancellation")
+	}
+	for i, r := range results {
+		if r.Err != nil {
+			t.Fatalf("result[%d]: expected the dispatched request to drain successfully, got Err=%v", i, r.Err)
+		}
+		if r.Task.GUID != "x" {
+			t.Fatalf("result[%d]: expected the GUID to be recorded, got %q", i, r.Task.GUID)
+		}
+	}
+	if atomic.LoadInt32(&inFlight) != 5 {
+		t.Fatalf("expected all 5 requests to have been dispatched, got %d", inFlight)
+	}
+}
+
+// TerminateTask itself must surface a failure status rather than silently
+// wrapping a nil err into a nil return.
+func TestTerminateTaskSurfacesFailureStatus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer srv.Close()
+
+	c := &Client{BaseURL: srv.URL, HTTPClient: &http.Client{}}
+	if err := c.TerminateTask("missing-guid"); err == nil {
+		t.Fatalf("expected an error for a 404 response")
+	}
+}
+
+// TerminateTasks must surface a per-request failure for a guid the API
+// refused to cancel, not a nil Err from a nil-wrapped error.
+func TestTerminateTasksReportsFailure(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer srv.Close()
+
+	c := &Client{BaseURL: srv.URL, HTTPClient: &http.Client{}}
+	results, err := c.TerminateTasks(context.Background(), []string{"missing-guid"}, BulkOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if results[0].Err == nil {
+		t.Fatalf("expected a per-request error for a 404 response, got nil")
+	}
+}
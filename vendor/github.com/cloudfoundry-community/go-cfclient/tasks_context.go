@@ -0,0 +1,257 @@
+package cfclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// TaskClient wraps Client with a default deadline applied to every
+// *WithContext task call that doesn't already carry one of its own. The
+// nozzle's long-lived polling loops construct one via Client.WithDefaultTimeout
+// so a single slow or hung CF API call can't block the loop indefinitely.
+type TaskClient struct {
+	*Client
+	defaultTimeout time.Duration
+	retryPolicy    RetryPolicy
+}
+
+// WithDefaultTimeout returns a TaskClient bounding every *WithContext task
+// call to at most d whenever the caller's own context carries no deadline.
+func (c *Client) WithDefaultTimeout(d time.Duration) *TaskClient {
+	return &TaskClient{Client: c, defaultTimeout: d}
+}
+
+// WithDefaultTimeout returns a copy of tc with its default timeout replaced
+// by d, so it can be chained with WithRetryPolicy in either order.
+func (tc *TaskClient) WithDefaultTimeout(d time.Duration) *TaskClient {
+	next := *tc
+	next.defaultTimeout = d
+	return &next
+}
+
+// boundContext applies tc's default timeout to ctx when ctx has no deadline
+// of its own. The returned cancel func must always be called by the caller.
+func (tc *TaskClient) boundContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if tc.defaultTimeout <= 0 {
+		return ctx, func() {}
+	}
+	if _, ok := ctx.Deadline(); ok {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, tc.defaultTimeout)
+}
+
+// runTaskOp runs fn on a per-operation cancel channel: the channel is closed
+// either by fn finishing on its own or by ctx being cancelled or timing out,
+// whichever happens first. fn keeps running to completion in the background
+// in the latter case, since DoRequest has no way to abort an in-flight
+// socket read, but the caller is released as soon as ctx says to give up.
+func runTaskOp(ctx context.Context, fn func() error) error {
+	done := make(chan error, 1)
+	go func() {
+		done <- fn()
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return errors.Wrap(ctx.Err(), "Task operation cancelled")
+	}
+}
+
+// ListTasksWithContext is ListTasks, cancellable via ctx.
+func (c *Client) ListTasksWithContext(ctx context.Context) ([]Task, error) {
+	var tasks []Task
+	err := runTaskOp(ctx, func() error {
+		var err error
+		tasks, err = c.ListTasks()
+		return err
+	})
+	return tasks, err
+}
+
+// ListTasksWithContext overrides Client.ListTasksWithContext to apply tc's
+// default timeout and retry policy.
+func (tc *TaskClient) ListTasksWithContext(ctx context.Context) ([]Task, error) {
+	ctx, cancel := tc.boundContext(ctx)
+	defer cancel()
+
+	resp, err := tc.sendWithRetry(ctx, "GET", "/v3/tasks", nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error requesting tasks")
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error reading tasks")
+	}
+	response, err := parseTaskListRespones(body)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error reading tasks")
+	}
+	return response.Tasks, nil
+}
+
+// ListAllTasksWithContext is ListAllTasks, cancellable via ctx.
+func (c *Client) ListAllTasksWithContext(ctx context.Context, opts TaskListOptions) ([]Task, error) {
+	var tasks []Task
+	err := runTaskOp(ctx, func() error {
+		var err error
+		tasks, err = c.ListAllTasks(opts)
+		return err
+	})
+	return tasks, err
+}
+
+// TasksByAppAllWithContext is TasksByAppAll, cancellable via ctx.
+func (c *Client) TasksByAppAllWithContext(ctx context.Context, guid string, opts TaskListOptions) ([]Task, error) {
+	var tasks []Task
+	err := runTaskOp(ctx, func() error {
+		var err error
+		tasks, err = c.TasksByAppAll(guid, opts)
+		return err
+	})
+	return tasks, err
+}
+
+// CreateTaskWithContext is CreateTask, cancellable via ctx.
+func (c *Client) CreateTaskWithContext(ctx context.Context, tr TaskRequest) (Task, error) {
+	var task Task
+	err := runTaskOp(ctx, func() error {
+		var err error
+		task, err = c.CreateTask(tr)
+		return err
+	})
+	return task, err
+}
+
+// CreateTaskWithContext overrides Client.CreateTaskWithContext to apply tc's
+// default timeout and retry policy.
+func (tc *TaskClient) CreateTaskWithContext(ctx context.Context, tr TaskRequest) (task Task, err error) {
+	ctx, cancel := tc.boundContext(ctx)
+	defer cancel()
+
+	bodyReader, err := createReader(tr)
+	if err != nil {
+		return task, err
+	}
+	bodyBytes, err := ioutil.ReadAll(bodyReader)
+	if err != nil {
+		return task, errors.Wrap(err, "Error reading task request body")
+	}
+
+	resp, err := tc.sendWithRetry(ctx, "POST", fmt.Sprintf("/v3/apps/%s/tasks", tr.DropletGUID), bodyBytes)
+	if err != nil {
+		return task, errors.Wrap(err, "Error creating task")
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return task, errors.Wrap(err, "Error reading task after creation")
+	}
+	err = json.Unmarshal(body, &task)
+	if err != nil {
+		return task, errors.Wrap(err, "Error unmarshaling task")
+	}
+	return task, err
+}
+
+// TaskByGuidWithContext is TaskByGuid, cancellable via ctx.
+func (c *Client) TaskByGuidWithContext(ctx context.Context, guid string) (Task, error) {
+	var task Task
+	err := runTaskOp(ctx, func() error {
+		var err error
+		task, err = c.TaskByGuid(guid)
+		return err
+	})
+	return task, err
+}
+
+// TaskByGuidWithContext overrides Client.TaskByGuidWithContext to apply tc's
+// default timeout and retry policy.
+func (tc *TaskClient) TaskByGuidWithContext(ctx context.Context, guid string) (task Task, err error) {
+	ctx, cancel := tc.boundContext(ctx)
+	defer cancel()
+
+	resp, err := tc.sendWithRetry(ctx, "GET", fmt.Sprintf("/v3/tasks/%s", guid), nil)
+	if err != nil {
+		return task, errors.Wrap(err, "Error requesting task")
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return task, errors.Wrap(err, "Error reading task")
+	}
+	err = json.Unmarshal(body, &task)
+	if err != nil {
+		return task, errors.Wrap(err, "Error unmarshaling task")
+	}
+	return task, err
+}
+
+// TasksByAppWithContext is TasksByApp, cancellable via ctx.
+func (c *Client) TasksByAppWithContext(ctx context.Context, guid string) ([]Task, error) {
+	var tasks []Task
+	err := runTaskOp(ctx, func() error {
+		var err error
+		tasks, err = c.TasksByApp(guid)
+		return err
+	})
+	return tasks, err
+}
+
+// TasksByAppWithContext overrides Client.TasksByAppWithContext to apply tc's
+// default timeout and retry policy.
+func (tc *TaskClient) TasksByAppWithContext(ctx context.Context, guid string) ([]Task, error) {
+	ctx, cancel := tc.boundContext(ctx)
+	defer cancel()
+
+	resp, err := tc.sendWithRetry(ctx, "GET", fmt.Sprintf("/v3/apps/%s/tasks", guid), nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error requesting task")
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error reading tasks")
+	}
+	response, err := parseTaskListRespones(body)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error parsing tasks")
+	}
+	return response.Tasks, nil
+}
+
+// TerminateTaskWithContext is TerminateTask, cancellable via ctx.
+func (c *Client) TerminateTaskWithContext(ctx context.Context, guid string) error {
+	return runTaskOp(ctx, func() error {
+		return c.TerminateTask(guid)
+	})
+}
+
+// TerminateTaskWithContext overrides Client.TerminateTaskWithContext to apply
+// tc's default timeout and retry policy.
+func (tc *TaskClient) TerminateTaskWithContext(ctx context.Context, guid string) error {
+	ctx, cancel := tc.boundContext(ctx)
+	defer cancel()
+
+	resp, err := tc.sendWithRetry(ctx, "PUT", fmt.Sprintf("/v3/tasks/%s/cancel", guid), nil)
+	if err != nil {
+		return errors.Wrap(err, "Error terminating task")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 202 {
+		return errors.Errorf("Failed terminating task, response status code %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package cfclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// A TaskClient's default timeout must bound the in-flight HTTP round trip
+// itself, not just the gap between retry attempts.
+func TestTaskClientDefaultTimeoutBoundsInFlightRequest(t *testing.T) {
+	block := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-block
+	}))
+	defer func() {
+		close(block)
+		srv.Close()
+	}()
+
+	c := &Client{BaseURL: srv.URL, HTTPClient: &http.Client{}}
+	tc := c.WithDefaultTimeout(50 * time.Millisecond)
+
+	start := time.Now()
+	_, err := tc.ListTasksWithContext(context.Background())
+	elapsed := time.Since(start)
+
+	if err == nil {
+		t.Fatalf("expected a deadline-exceeded error")
+	}
+	if elapsed > 200*time.Millisecond {
+		t.Fatalf("expected the call to return near the 50ms default timeout, took %s", elapsed)
+	}
+}
+
+// The runTaskOp-based Client.ListTasksWithContext already returns promptly
+// on cancellation, releasing the caller while the real request keeps
+// running in the background; this just pins that documented behavior.
+func TestClientListTasksWithContextReturnsOnCancel(t *testing.T) {
+	block := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-block
+	}))
+	defer func() {
+		close(block)
+		srv.Close()
+	}()
+
+	c := &Client{BaseURL: srv.URL, HTTPClient: &http.Client{}}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := c.ListTasksWithContext(ctx)
+	elapsed := time.Since(start)
+	if elapsed > 200*time.Millisecond {
+		t.Fatalf("expected early return near the 50ms deadline, took %s", elapsed)
+	}
+	if err == nil {
+		t.Fatalf("expected a cancellation error")
+	}
+}
+
+// WithRetryPolicy and WithDefaultTimeout must both be preservable regardless
+// of chaining order; dropping either one silently defeats the other.
+func TestRetryPolicyAndDefaultTimeoutChainEitherOrder(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 7}
+	timeout := 5 * time.Second
+
+	c := &Client{}
+
+	tc := c.WithRetryPolicy(policy).WithDefaultTimeout(timeout)
+	if tc.retryPolicy.MaxAttempts != policy.MaxAttempts {
+		t.Errorf("WithRetryPolicy().WithDefaultTimeout(): retryPolicy.MaxAttempts = %d, want %d", tc.retryPolicy.MaxAttempts, policy.MaxAttempts)
+	}
+	if tc.defaultTimeout != timeout {
+		t.Errorf("WithRetryPolicy().WithDefaultTimeout(): defaultTimeout = %s, want %s", tc.defaultTimeout, timeout)
+	}
+
+	tc = c.WithDefaultTimeout(timeout).WithRetryPolicy(policy)
+	if tc.retryPolicy.MaxAttempts != policy.MaxAttempts {
+		t.Errorf("WithDefaultTimeout().WithRetryPolicy(): retryPolicy.MaxAttempts = %d, want %d", tc.retryPolicy.MaxAttempts, policy.MaxAttempts)
+	}
+	if tc.defaultTimeout != timeout {
+		t.Errorf("WithDefaultTimeout().WithRetryPolicy(): defaultTimeout = %s, want %s", tc.defaultTimeout, timeout)
+	}
+}
@@ -0,0 +1,190 @@
+package cfclient
+
+import (
+	"bytes"
+	"context"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// DefaultRetryableStatusCodes are the response codes retried when a
+// RetryPolicy doesn't specify its own.
+var DefaultRetryableStatusCodes = []int{429, 500, 502, 503, 504}
+
+// RetryPolicy controls how a TaskClient retries a task request that comes
+// back with a retryable status code.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of tries, including the first one.
+	// A policy with MaxAttempts <= 1 never retries.
+	MaxAttempts int
+	// BaseDelay is the starting backoff for the full-jitter exponential
+	// schedule used on 5xx responses.
+	BaseDelay time.Duration
+	// MaxDelay caps both the 5xx backoff and how long a 429's
+	// X-RateLimit-Reset/Retry-After is allowed to push the wait out to.
+	MaxDelay time.Duration
+	// RetryableStatusCodes overrides DefaultRetryableStatusCodes when set.
+	RetryableStatusCodes []int
+	// OnRetry, if set, is called after each retryable response, before the
+	// backoff sleep, so callers can track attempts/backoff as metrics.
+	OnRetry func(attempt int, backoff time.Duration, statusCode int)
+}
+
+// WithRetryPolicy returns a TaskClient that retries task requests per p.
+func (c *Client) WithRetryPolicy(p RetryPolicy) *TaskClient {
+	return &TaskClient{Client: c, retryPolicy: p}
+}
+
+// WithRetryPolicy returns a copy of tc with its retry policy replaced by p,
+// so it can be chained with WithDefaultTimeout.
+func (tc *TaskClient) WithRetryPolicy(p RetryPolicy) *TaskClient {
+	next := *tc
+	next.retryPolicy = p
+	return &next
+}
+
+func (p RetryPolicy) retryableStatusCodes() []int {
+	if len(p.RetryableStatusCodes) > 0 {
+		return p.RetryableStatusCodes
+	}
+	return DefaultRetryableStatusCodes
+}
+
+func (p RetryPolicy) isRetryable(statusCode int) bool {
+	for _, code := range p.retryableStatusCodes() {
+		if code == statusCode {
+			return true
+		}
+	}
+	return false
+}
+
+// backoffFor computes how long to sleep after a retryable response. 429s
+// honor X-RateLimit-Reset (unix seconds) or Retry-After (seconds) when
+// present; everything else uses full-jitter exponential backoff.
+func (p RetryPolicy) backoffFor(resp *http.Response, attempt int) time.Duration {
+	maxDelay := p.MaxDelay
+	if maxDelay <= 0 {
+		maxDelay = 30 * time.Second
+	}
+	if resp.StatusCode == http.StatusTooManyRequests {
+		if d, ok := retryAfterHeader(resp, maxDelay); ok {
+			return d
+		}
+	}
+	base := p.BaseDelay
+	if base <= 0 {
+		base = 500 * time.Millisecond
+	}
+	window := base << uint(attempt)
+	if window <= 0 || window > maxDelay {
+		window = maxDelay
+	}
+	return time.Duration(rand.Int63n(int64(window) + 1))
+}
+
+// retryAfterHeader reads Retry-After (seconds) or X-RateLimit-Reset (unix
+// seconds) off resp, clamped to max, returning ok=false if neither is set.
+func retryAfterHeader(resp *http.Response, maxWait time.Duration) (time.Duration, bool) {
+	if v := resp.Header.Get("Retry-After"); v != "" {
+		if secs, err := strconv.Atoi(v); err == nil {
+			return clampDuration(time.Duration(secs)*time.Second, maxWait), true
+		}
+	}
+	if v := resp.Header.Get("X-RateLimit-Reset"); v != "" {
+		if epoch, err := strconv.ParseInt(v, 10, 64); err == nil {
+			wait := time.Until(time.Unix(epoch, 0))
+			if wait < 0 {
+				wait = 0
+			}
+			return clampDuration(wait, maxWait), true
+		}
+	}
+	return 0, false
+}
+
+func clampDuration(d, maxD time.Duration) time.Duration {
+	if d > maxD {
+		return maxD
+	}
+	if d < 0 {
+		return 0
+	}
+	return d
+}
+
+// sendWithRetry issues method/path (with an optional body) via the
+// underlying Client, retrying per tc.retryPolicy on a retryable status code.
+// The final response is returned unconsumed; the caller owns closing its body.
+func (tc *TaskClient) sendWithRetry(ctx context.Context, method, path string, body []byte) (*http.Response, error) {
+	policy := tc.retryPolicy
+	if policy.MaxAttempts <= 0 {
+		policy.MaxAttempts = 1
+	}
+
+	for attempt := 1; ; attempt++ {
+		resp, err := tc.sendOnce(ctx, method, path, body)
+		if err != nil {
+			return nil, err
+		}
+		if attempt >= policy.MaxAttempts || !policy.isRetryable(resp.StatusCode) {
+			return resp, nil
+		}
+
+		wait := policy.backoffFor(resp, attempt)
+		resp.Body.Close()
+		if policy.OnRetry != nil {
+			policy.OnRetry(attempt, wait, resp.StatusCode)
+		}
+
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return nil, errors.Wrap(ctx.Err(), "Task operation cancelled during retry backoff")
+		}
+	}
+}
+
+// send issues a single method/path request without retrying.
+func (tc *TaskClient) send(method, path string, body []byte) (*http.Response, error) {
+	if body == nil {
+		return tc.Client.DoRequest(tc.Client.NewRequest(method, path))
+	}
+	return tc.Client.DoRequest(tc.Client.NewRequestWithBody(method, path, bytes.NewReader(body)))
+}
+
+// sendOnce runs send on its own goroutine and races it against ctx, so a
+// hung round trip can't block the caller past ctx's deadline the way a bare
+// call to send would. Unlike the retry loop around it, this only bounds a
+// single attempt: sendWithRetry's own select on ctx.Done() during the
+// backoff sleep is what stops a cancelled caller from being retried forever.
+// If ctx wins the race, send keeps running in the background (DoRequest has
+// no way to abort an in-flight socket read, same as runTaskOp); its response
+// body is closed once it eventually arrives so the connection isn't leaked.
+func (tc *TaskClient) sendOnce(ctx context.Context, method, path string, body []byte) (*http.Response, error) {
+	type result struct {
+		resp *http.Response
+		err  error
+	}
+	done := make(chan result, 1)
+	go func() {
+		resp, err := tc.send(method, path, body)
+		done <- result{resp, err}
+	}()
+
+	select {
+	case r := <-done:
+		return r.resp, r.err
+	case <-ctx.Done():
+		go func() {
+			if r := <-done; r.resp != nil {
+				r.resp.Body.Close()
+			}
+		}()
+		return nil, errors.Wrap(ctx.Err(), "Task operation cancelled")
+	}
+}
@@ -0,0 +1,117 @@
+package cfclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestTaskClientRetriesOnRetryableStatusThenSucceeds(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"resources":[]}`))
+	}))
+	defer srv.Close()
+
+	c := &Client{BaseURL: srv.URL, HTTPClient: &http.Client{}}
+	var retried []int
+	tc := c.WithRetryPolicy(RetryPolicy{
+		MaxAttempts: 5,
+		BaseDelay:   time.Millisecond,
+		MaxDelay:    5 * time.Millisecond,
+		OnRetry: func(attempt int, backoff time.Duration, statusCode int) {
+			retried = append(retried, statusCode)
+		},
+	})
+
+	if _, err := tc.ListTasksWithContext(context.Background()); err != nil {
+		t.Fatalf("expected eventual success, got %v", err)
+	}
+	if calls != 3 {
+		t.Fatalf("expected 3 calls (2 retried + 1 success), got %d", calls)
+	}
+	if len(retried) != 2 || retried[0] != 503 || retried[1] != 503 {
+		t.Fatalf("expected OnRetry called twice with 503, got %v", retried)
+	}
+}
+
+func TestTaskClientGivesUpAfterMaxAttempts(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	c := &Client{BaseURL: srv.URL, HTTPClient: &http.Client{}}
+	tc := c.WithRetryPolicy(RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 5 * time.Millisecond})
+
+	resp, err := tc.sendWithRetry(context.Background(), "GET", "/v3/tasks", nil)
+	if err != nil {
+		t.Fatalf("expected the final (still-503) response, not a transport error: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("expected the final unretried 503 to be returned, got %d", resp.StatusCode)
+	}
+	if calls != 3 {
+		t.Fatalf("expected exactly MaxAttempts=3 calls, got %d", calls)
+	}
+}
+
+func TestTaskClientHonorsRetryAfterOn429(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"resources":[]}`))
+	}))
+	defer srv.Close()
+
+	c := &Client{BaseURL: srv.URL, HTTPClient: &http.Client{}}
+	tc := c.WithRetryPolicy(RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 5 * time.Millisecond})
+
+	start := time.Now()
+	if _, err := tc.ListTasksWithContext(context.Background()); err != nil {
+		t.Fatalf("expected eventual success, got %v", err)
+	}
+	if time.Since(start) > 500*time.Millisecond {
+		t.Fatalf("expected Retry-After: 0 to be honored without excessive delay")
+	}
+	if calls != 2 {
+		t.Fatalf("expected 2 calls, got %d", calls)
+	}
+}
+
+func TestSendWithRetryCancelledDuringBackoff(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	c := &Client{BaseURL: srv.URL, HTTPClient: &http.Client{}}
+	tc := c.WithRetryPolicy(RetryPolicy{MaxAttempts: 5, BaseDelay: time.Second, MaxDelay: time.Second})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+
+	start := time.Now()
+	if _, err := tc.ListTasksWithContext(ctx); err == nil {
+		t.Fatalf("expected cancellation error")
+	}
+	if elapsed := time.Since(start); elapsed > 200*time.Millisecond {
+		t.Fatalf("expected prompt return on cancellation during backoff, took %s", elapsed)
+	}
+}
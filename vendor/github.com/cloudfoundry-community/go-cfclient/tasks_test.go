@@ -0,0 +1,26 @@
+package cfclient
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// A non-200 response mid-pagination must surface its real status code
+// rather than an unmarshal error from an empty body.
+func TestListAllTasksSurfacesStatusCodeOnFailure(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer srv.Close()
+
+	c := &Client{BaseURL: srv.URL, HTTPClient: &http.Client{}}
+	_, err := c.ListAllTasks(TaskListOptions{})
+	if err == nil {
+		t.Fatalf("expected an error")
+	}
+	if !strings.Contains(err.Error(), "403") {
+		t.Fatalf("expected the error to mention status code 403, got: %v", err)
+	}
+}
@@ -0,0 +1,146 @@
+package cfclient
+
+import (
+	"context"
+	"math/rand"
+	"time"
+)
+
+// TaskEventType identifies the kind of change a TaskWatcher observed between
+// two polls of the CF v3 tasks API.
+type TaskEventType string
+
+const (
+	// TaskCreated is emitted the first time a task's GUID is observed.
+	TaskCreated TaskEventType = "Created"
+	// TaskUpdated is emitted when a previously observed task's UpdatedAt
+	// timestamp advances, e.g. a PENDING task transitioning to RUNNING.
+	TaskUpdated TaskEventType = "Updated"
+	// TaskTerminated is emitted when a previously observed task no longer
+	// appears in the listing, e.g. after it drops out of the CC retention
+	// window following completion or cancellation.
+	TaskTerminated TaskEventType = "Terminated"
+)
+
+// TaskEvent describes a single task lifecycle change observed by a
+// TaskWatcher.
+type TaskEvent struct {
+	Type      TaskEventType
+	Task      Task
+	PrevState string
+}
+
+// TaskWatcherOptions configures a TaskWatcher.
+type TaskWatcherOptions struct {
+	// AppGUIDs restricts polling to tasks belonging to these apps. Empty
+	// means watch every task the user has access to.
+	AppGUIDs []string
+	// PollInterval is how often the watcher lists tasks when the previous
+	// poll succeeded. Defaults to 30s if zero.
+	PollInterval time.Duration
+	// MaxBackoff caps the jittered backoff applied after a failed poll.
+	// Defaults to 5 minutes if zero.
+	MaxBackoff time.Duration
+}
+
+// TaskWatcher polls ListAllTasks on an interval, diffs the result against the
+// previous snapshot by GUID and UpdatedAt, and emits a TaskEvent for every
+// task that was created, updated, or has disappeared since the last poll.
+//
+// TaskWatcher only produces the event stream; it does not itself forward
+// TaskEvents anywhere. The nozzle package's TaskMetricsPipeline is the
+// caller that drains Events() and records each one as a New Relic custom
+// event.
+type TaskWatcher struct {
+	client *Client
+	opts   TaskWatcherOptions
+	events chan TaskEvent
+	seen   map[string]Task
+}
+
+// NewTaskWatcher creates a TaskWatcher that will poll using client once
+// Start is called.
+func NewTaskWatcher(client *Client, opts TaskWatcherOptions) *TaskWatcher {
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = 30 * time.Second
+	}
+	if opts.MaxBackoff <= 0 {
+		opts.MaxBackoff = 5 * time.Minute
+	}
+	return &TaskWatcher{
+		client: client,
+		opts:   opts,
+		events: make(chan TaskEvent),
+		seen:   make(map[string]Task),
+	}
+}
+
+// Events returns the channel TaskEvents are delivered on. The channel is
+// closed once Start returns.
+func (w *TaskWatcher) Events() <-chan TaskEvent {
+	return w.events
+}
+
+// Start polls until ctx is cancelled, closing the Events channel before it
+// returns. It should be run in its own goroutine.
+func (w *TaskWatcher) Start(ctx context.Context) {
+	defer close(w.events)
+
+	backoff := w.opts.PollInterval
+	for {
+		tasks, err := w.client.ListAllTasksWithContext(ctx, TaskListOptions{AppGUIDs: w.opts.AppGUIDs})
+		if err != nil {
+			backoff = jitteredBackoff(backoff, w.opts.MaxBackoff)
+		} else {
+			w.diff(ctx, tasks)
+			backoff = w.opts.PollInterval
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+	}
+}
+
+// diff compares tasks against the previous snapshot and sends a TaskEvent
+// for every task that is new, changed, or has disappeared, then replaces the
+// snapshot with tasks. Sends respect ctx cancellation so Start can return
+// promptly even with no reader draining Events.
+func (w *TaskWatcher) diff(ctx context.Context, tasks []Task) {
+	current := make(map[string]Task, len(tasks))
+	for _, task := range tasks {
+		current[task.GUID] = task
+		prev, ok := w.seen[task.GUID]
+		switch {
+		case !ok:
+			w.send(ctx, TaskEvent{Type: TaskCreated, Task: task})
+		case !prev.UpdatedAt.Equal(task.UpdatedAt):
+			w.send(ctx, TaskEvent{Type: TaskUpdated, Task: task, PrevState: prev.State})
+		}
+	}
+	for guid, prev := range w.seen {
+		if _, ok := current[guid]; !ok {
+			w.send(ctx, TaskEvent{Type: TaskTerminated, Task: prev, PrevState: prev.State})
+		}
+	}
+	w.seen = current
+}
+
+func (w *TaskWatcher) send(ctx context.Context, event TaskEvent) {
+	select {
+	case w.events <- event:
+	case <-ctx.Done():
+	}
+}
+
+// jitteredBackoff doubles backoff, capped at max, and applies full jitter so
+// a thundering herd of watchers doesn't retry CC in lockstep after an error.
+func jitteredBackoff(backoff, max time.Duration) time.Duration {
+	next := backoff * 2
+	if next > max || next <= 0 {
+		next = max
+	}
+	return time.Duration(rand.Int63n(int64(next)))
+}
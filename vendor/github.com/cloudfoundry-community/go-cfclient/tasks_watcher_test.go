@@ -0,0 +1,42 @@
+package cfclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// Start must shut down promptly when ctx is cancelled, even mid-poll,
+// rather than waiting out the blocking ListAllTasks call.
+func TestTaskWatcherStartShutsDownPromptlyDuringInFlightPoll(t *testing.T) {
+	block := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-block
+	}))
+	defer func() {
+		close(block)
+		srv.Close()
+	}()
+
+	c := &Client{BaseURL: srv.URL, HTTPClient: &http.Client{}}
+	w := NewTaskWatcher(c, TaskWatcherOptions{PollInterval: time.Hour})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+	go func() {
+		w.Start(ctx)
+		close(stopped)
+	}()
+
+	// Give Start time to enter the blocking poll, then cancel.
+	time.Sleep(20 * time.Millisecond)
+	cancel()
+
+	select {
+	case <-stopped:
+	case <-time.After(200 * time.Millisecond):
+		t.Fatalf("Start did not shut down promptly after ctx cancellation")
+	}
+}